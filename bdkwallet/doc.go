@@ -0,0 +1,7 @@
+// Package bdkwallet wraps a BDK-backed wallet library for use by utreexod. It provides the
+// Manager and Wallet types that an RPC layer would dispatch wallet-affecting calls to (PSBT
+// construction/signing, passphrase unlock, wallet-name-qualified balances and transaction
+// history, event subscriptions, and so on) but does not itself define or register any RPC
+// methods or websocket endpoints: wiring Manager/Wallet up to the node's actual RPC surface is
+// out of scope for this package and is left to the node's RPC package.
+package bdkwallet
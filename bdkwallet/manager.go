@@ -1,8 +1,12 @@
 package bdkwallet
 
 import (
+	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/utreexo/utreexod/blockchain"
 	"github.com/utreexo/utreexod/btcutil"
@@ -12,6 +16,28 @@ import (
 
 var defaultWalletPath = "bdkwallet"
 var defaultWalletFileName = "default.dat"
+var walletIndexFileName = "index.json"
+
+// defaultWalletName is the name used for the wallet that RPC callers get when they don't
+// specify a wallet name, preserving the behavior of the original single-wallet Manager.
+const defaultWalletName = "default"
+
+// autoLockCheckInterval is how often the Manager checks for wallets whose unlock timeout
+// has expired.
+const autoLockCheckInterval = time.Second
+
+var (
+	// ErrWalletNotFound is returned when a requested wallet name isn't loaded.
+	ErrWalletNotFound = errors.New("wallet not found")
+	// ErrWalletExists is returned by CreateWallet when the given name is already in use.
+	ErrWalletExists = errors.New("wallet already exists")
+
+	// ErrNoWallet is a deprecated alias of ErrWalletNotFound, kept for callers that
+	// predate named multi-wallet support.
+	//
+	// Deprecated: use ErrWalletNotFound.
+	ErrNoWallet = ErrWalletNotFound
+)
 
 // ManagerConfig is a configuration struct used to
 type ManagerConfig struct {
@@ -21,14 +47,38 @@ type ManagerConfig struct {
 	DataDir     string
 }
 
+// walletRecord is a loaded wallet together with the metadata we persist about it in the
+// wallet index.
+type walletRecord struct {
+	wallet         Wallet
+	descriptorType string
+
+	// unlockDeadline is when the wallet should be auto-locked. The zero value means the
+	// wallet was unlocked with no timeout, or is locked.
+	unlockDeadline time.Time
+}
+
+// walletIndexEntry is the on-disk representation of a loaded wallet in the index file.
+type walletIndexEntry struct {
+	Name           string `json:"name"`
+	DescriptorType string `json:"descriptor_type,omitempty"`
+}
+
 // Manager handles the configuration and handling data in between the utreexo node
-// and the bdk wallet library.
+// and the bdk wallet library. Wallet-affecting methods take a wallet name argument
+// (resolved through resolveWalletName, with "" meaning the default wallet) so that a caller
+// dispatching to these methods on behalf of multiple wallets can stay wallet-name-qualified.
 type Manager struct {
-	config ManagerConfig
+	config    ManagerConfig
+	walletDir string
+
+	mu      sync.RWMutex
+	wallets map[string]*walletRecord // wallets does not need a per-wallet mutex as that's done in Rust
 
-	// Wallet is the underlying wallet that calls out to the
-	// bdk rust library.
-	Wallet Wallet // wallet does not need a mutex as it's done in Rust
+	subscribers map[uint64]*subscriber
+	nextSubID   uint64
+
+	quit chan struct{}
 }
 
 func WalletDir(dataDir string) string {
@@ -46,6 +96,16 @@ func DoesWalletDirExist(dataDir string) (bool, error) {
 	return true, nil
 }
 
+// walletDBPath returns the on-disk database path for the named wallet. The default wallet
+// keeps its historical file name for backward compatibility with data directories created
+// before named wallets existed.
+func walletDBPath(walletDir, name string) string {
+	if name == defaultWalletName {
+		return filepath.Join(walletDir, defaultWalletFileName)
+	}
+	return filepath.Join(walletDir, name+".dat")
+}
+
 func NewManager(config ManagerConfig) (*Manager, error) {
 	factory, err := factory()
 	if err != nil {
@@ -57,60 +117,499 @@ func NewManager(config ManagerConfig) (*Manager, error) {
 		return nil, err
 	}
 
-	dbPath := filepath.Join(walletDir, defaultWalletFileName)
-	var wallet Wallet
-	if _, err := os.Stat(dbPath); err != nil {
-		if !os.IsNotExist(err) {
-			return nil, err
+	m := &Manager{
+		config:      config,
+		walletDir:   walletDir,
+		wallets:     make(map[string]*walletRecord),
+		subscribers: make(map[uint64]*subscriber),
+		quit:        make(chan struct{}),
+	}
+
+	index, found, err := loadWalletIndex(walletDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		// No index file yet: adopt the pre-existing default wallet if one is on disk,
+		// otherwise create it. This preserves the behavior of the original
+		// single-wallet Manager for upgraded data directories. An index file that
+		// exists but lists zero wallets means the default wallet was explicitly
+		// unloaded, and must stay unloaded across restarts.
+		dbPath := walletDBPath(walletDir, defaultWalletName)
+		var wallet Wallet
+		if _, err := os.Stat(dbPath); err != nil {
+			if !os.IsNotExist(err) {
+				return nil, err
+			}
+			if wallet, err = factory.Create(dbPath, nil, nil, nil, config.ChainParams); err != nil {
+				return nil, err
+			}
+		} else {
+			if wallet, err = factory.Load(dbPath, config.ChainParams); err != nil {
+				return nil, err
+			}
 		}
-		if wallet, err = factory.Create(dbPath, config.ChainParams); err != nil {
+		m.wallets[defaultWalletName] = &walletRecord{wallet: wallet}
+		if err := m.saveWalletIndexLocked(); err != nil {
 			return nil, err
 		}
 	} else {
-		if wallet, err = factory.Load(dbPath, config.ChainParams); err != nil {
-			return nil, err
+		for _, entry := range index {
+			wallet, err := factory.Load(walletDBPath(walletDir, entry.Name), config.ChainParams)
+			if err != nil {
+				return nil, err
+			}
+			m.wallets[entry.Name] = &walletRecord{wallet: wallet, descriptorType: entry.DescriptorType}
 		}
 	}
 
-	m := Manager{
-		config: config,
-		Wallet: wallet,
-	}
 	if config.Chain != nil {
 		// Subscribe to new blocks/reorged blocks.
 		config.Chain.Subscribe(m.handleBlockchainNotification)
 	}
 
+	go m.autoLockLoop()
+
 	log.Info("Started the BDK wallet manager.")
-	return &m, nil
+	return m, nil
 }
 
-func (m *Manager) NotifyNewTransactions(txns []*mempool.TxDesc) {
-	if m.Wallet == nil {
-		return
+// Stop shuts down the Manager's background goroutines.
+func (m *Manager) Stop() {
+	close(m.quit)
+}
+
+// autoLockLoop periodically locks wallets whose unlock timeout has expired.
+func (m *Manager) autoLockLoop() {
+	ticker := time.NewTicker(autoLockCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.lockExpiredWallets()
+		case <-m.quit:
+			return
+		}
+	}
+}
+
+// lockExpiredWallets locks every loaded wallet whose unlock deadline has passed. Wallet.Lock
+// is called outside of m.mu so one wallet's lock call can't stall Manager operations on the
+// others (e.g. block/mempool fan-out in handleBlockchainNotification/NotifyNewTransactions).
+//
+// Because of that, the deadline is re-checked under m.mu immediately before calling Lock, and
+// again before clearing it afterwards: a concurrent WalletPassphrase call may have re-unlocked
+// the wallet with a fresh deadline while we weren't holding the lock, and we must not clear
+// that new deadline out from under it.
+func (m *Manager) lockExpiredWallets() {
+	now := time.Now()
+
+	m.mu.RLock()
+	due := make(map[string]Wallet)
+	for name, rec := range m.wallets {
+		if !rec.unlockDeadline.IsZero() && !now.Before(rec.unlockDeadline) {
+			due[name] = rec.wallet
+		}
+	}
+	m.mu.RUnlock()
+
+	for name, wallet := range due {
+		m.mu.Lock()
+		rec, ok := m.wallets[name]
+		if !ok || rec.unlockDeadline.IsZero() || now.Before(rec.unlockDeadline) {
+			// No longer due: the wallet was unloaded, locked, or re-unlocked with a
+			// fresh deadline since we took the snapshot above.
+			m.mu.Unlock()
+			continue
+		}
+		deadlineAtCheck := rec.unlockDeadline
+		m.mu.Unlock()
+
+		if err := wallet.Lock(); err != nil {
+			log.Errorf("Failed to auto-lock wallet %q. %v", name, err)
+			continue
+		}
+
+		m.mu.Lock()
+		if rec, ok := m.wallets[name]; ok && rec.unlockDeadline.Equal(deadlineAtCheck) {
+			rec.unlockDeadline = time.Time{}
+		}
+		m.mu.Unlock()
+	}
+}
+
+// loadWalletIndex reads the wallet index file from walletDir. The found return value is
+// false only when the index file itself doesn't exist yet; an existing index that lists zero
+// wallets (e.g. because the last one was explicitly unloaded) still reports found=true with a
+// nil/empty slice, so callers can tell "never indexed" apart from "intentionally empty".
+func loadWalletIndex(walletDir string) (index []walletIndexEntry, found bool, err error) {
+	raw, err := os.ReadFile(filepath.Join(walletDir, walletIndexFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	if err := json.Unmarshal(raw, &index); err != nil {
+		return nil, false, err
+	}
+	return index, true, nil
+}
+
+// saveWalletIndexLocked writes the current set of loaded wallets to the index file. The
+// caller must hold m.mu.
+func (m *Manager) saveWalletIndexLocked() error {
+	index := make([]walletIndexEntry, 0, len(m.wallets))
+	for name, rec := range m.wallets {
+		index = append(index, walletIndexEntry{Name: name, DescriptorType: rec.descriptorType})
+	}
+	raw, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(m.walletDir, walletIndexFileName), raw, 0o600)
+}
+
+// CreateWallet creates and loads a new wallet with the given name. If seed is nil, the
+// wallet generates its own mnemonic.
+//
+// factory.Create runs key derivation and disk I/O, so it is called outside of m.mu: holding
+// the lock for its duration would stall every other Manager operation, including
+// block/mempool fan-out to already-loaded wallets in
+// handleBlockchainNotification/NotifyNewTransactions. The existence check is re-validated
+// under m.mu once the wallet is created, in case a concurrent call raced to the same name.
+func (m *Manager) CreateWallet(name string, seed []byte, opts CreateOpts) (Wallet, error) {
+	factory, err := factory()
+	if err != nil {
+		return nil, err
+	}
+
+	if m.walletExists(name) {
+		return nil, ErrWalletExists
+	}
+
+	wallet, err := factory.Create(
+		walletDBPath(m.walletDir, name), opts.PublicPassphrase, opts.PrivatePassphrase, seed, m.config.ChainParams,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.wallets[name]; exists {
+		return nil, ErrWalletExists
+	}
+	return wallet, m.addWalletLocked(name, wallet, opts.DescriptorType)
+}
+
+// CreateWatchOnlyWallet creates and loads a new watch-only wallet with the given name from
+// a BIP-380 output descriptor. A watch-only wallet can derive addresses, scan blocks, and
+// report balance/UTXOs, but rejects CreateTx with ErrWatchOnly. MnemonicWords has no error
+// return, so it does not report ErrWatchOnly.
+//
+// factory.CreateWatchOnly runs disk I/O, so it is called outside of m.mu for the same reason
+// as CreateWallet, with the same re-validated existence check.
+func (m *Manager) CreateWatchOnlyWallet(name string, descriptor string, opts CreateOpts) (Wallet, error) {
+	factory, err := factory()
+	if err != nil {
+		return nil, err
+	}
+
+	if m.walletExists(name) {
+		return nil, ErrWalletExists
+	}
+
+	wallet, err := factory.CreateWatchOnly(walletDBPath(m.walletDir, name), descriptor, m.config.ChainParams)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.wallets[name]; exists {
+		return nil, ErrWalletExists
+	}
+	return wallet, m.addWalletLocked(name, wallet, opts.DescriptorType)
+}
+
+// walletExists reports whether name is already loaded.
+func (m *Manager) walletExists(name string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, exists := m.wallets[name]
+	return exists
+}
+
+// loadedWallet returns the wallet registered under the exact name given, without the
+// empty-name-means-default resolution that Wallet applies.
+func (m *Manager) loadedWallet(name string) (Wallet, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	rec, ok := m.wallets[name]
+	if !ok {
+		return nil, false
+	}
+	return rec.wallet, true
+}
+
+// addWalletLocked registers wallet under name and persists the wallet index. The caller
+// must hold m.mu, and must not have already registered name.
+func (m *Manager) addWalletLocked(name string, wallet Wallet, descriptorType string) error {
+	m.wallets[name] = &walletRecord{wallet: wallet, descriptorType: descriptorType}
+	if err := m.saveWalletIndexLocked(); err != nil {
+		delete(m.wallets, name)
+		return err
+	}
+	return nil
+}
+
+// LoadWallet loads a previously created wallet with the given name from disk. It is a
+// no-op that returns the existing wallet if name is already loaded.
+//
+// factory.Load runs disk I/O, so it is called outside of m.mu for the same reason as
+// CreateWallet.
+func (m *Manager) LoadWallet(name string) (Wallet, error) {
+	if wallet, ok := m.loadedWallet(name); ok {
+		return wallet, nil
+	}
+
+	factory, err := factory()
+	if err != nil {
+		return nil, err
+	}
+
+	dbPath := walletDBPath(m.walletDir, name)
+	if _, err := os.Stat(dbPath); err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrWalletNotFound
+		}
+		return nil, err
+	}
+
+	wallet, err := factory.Load(dbPath, m.config.ChainParams)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if rec, ok := m.wallets[name]; ok {
+		return rec.wallet, nil
+	}
+	m.wallets[name] = &walletRecord{wallet: wallet}
+	if err := m.saveWalletIndexLocked(); err != nil {
+		delete(m.wallets, name)
+		return nil, err
+	}
+	return wallet, nil
+}
+
+// UnloadWallet removes a wallet from memory and the wallet index. It does not delete the
+// wallet's on-disk database, so it can be re-loaded later with LoadWallet.
+func (m *Manager) UnloadWallet(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.wallets[name]; !ok {
+		return ErrWalletNotFound
+	}
+	delete(m.wallets, name)
+	return m.saveWalletIndexLocked()
+}
+
+// ListWallets returns the names of all currently loaded wallets.
+func (m *Manager) ListWallets() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	names := make([]string, 0, len(m.wallets))
+	for name := range m.wallets {
+		names = append(names, name)
+	}
+	return names
+}
+
+// resolveWalletName maps an empty wallet name to the default wallet, for backward
+// compatibility with callers that predate named wallets.
+func resolveWalletName(name string) string {
+	if name == "" {
+		return defaultWalletName
+	}
+	return name
+}
+
+// Wallet returns the named wallet, or false if no wallet with that name is loaded. An
+// empty name refers to the default wallet, for backward compatibility with callers that
+// predate named wallets.
+func (m *Manager) Wallet(name string) (Wallet, bool) {
+	name = resolveWalletName(name)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	rec, ok := m.wallets[name]
+	if !ok {
+		return nil, false
+	}
+	return rec.wallet, true
+}
+
+// WalletPassphrase unlocks the named wallet's private key material with passphrase. If
+// timeout is greater than zero, the wallet is automatically re-locked after it elapses.
+// Unlock runs a deliberately slow KDF, so it is called outside of m.mu: holding the lock for
+// its duration would stall every other Manager operation, including block/mempool fan-out
+// to other wallets in handleBlockchainNotification/NotifyNewTransactions.
+func (m *Manager) WalletPassphrase(walletName string, passphrase []byte, timeout time.Duration) error {
+	name := resolveWalletName(walletName)
+
+	wallet, ok := m.Wallet(name)
+	if !ok {
+		return ErrWalletNotFound
+	}
+
+	if err := wallet.Unlock(passphrase, timeout); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if rec, ok := m.wallets[name]; ok {
+		if timeout > 0 {
+			rec.unlockDeadline = time.Now().Add(timeout)
+		} else {
+			rec.unlockDeadline = time.Time{}
+		}
 	}
+	return nil
+}
+
+// FundPSBT selects UTXOs for the given recipients and returns an unsigned PSBT with
+// derivation paths and bip32 metadata filled in for each input, so that external signers and
+// hardware wallets can take part in constructing a transaction.
+func (m *Manager) FundPSBT(walletName string, feerate uint64, recipients []Recipient, opts *PSBTOptions) ([]byte, error) {
+	wallet, ok := m.Wallet(walletName)
+	if !ok {
+		return nil, ErrWalletNotFound
+	}
+	return wallet.FundPSBT(feerate, recipients, opts)
+}
 
-	if err := m.Wallet.ApplyMempoolTransactions(txns); err != nil {
-		log.Errorf("Failed to apply mempool txs to the wallet. %v", err)
+// SignPSBT adds partial signatures for any inputs the named wallet owns, without
+// finalizing.
+func (m *Manager) SignPSBT(walletName string, psbt []byte) ([]byte, error) {
+	wallet, ok := m.Wallet(walletName)
+	if !ok {
+		return nil, ErrWalletNotFound
+	}
+	return wallet.SignPSBT(psbt)
+}
+
+// FinalizePSBT finalizes a fully-signed PSBT, returning both the finalized PSBT and the
+// serialized raw transaction extracted from it.
+func (m *Manager) FinalizePSBT(walletName string, psbt []byte) ([]byte, []byte, error) {
+	wallet, ok := m.Wallet(walletName)
+	if !ok {
+		return nil, nil, ErrWalletNotFound
+	}
+	return wallet.FinalizePSBT(psbt)
+}
+
+// ListTransactions returns a page of the named wallet's transaction history matching req.
+func (m *Manager) ListTransactions(walletName string, req TxQuery) (TxPage, error) {
+	wallet, ok := m.Wallet(walletName)
+	if !ok {
+		return TxPage{}, ErrWalletNotFound
+	}
+	return wallet.ListTransactions(req)
+}
+
+// FreshAddress returns a new, never-before-handed-out address from the named wallet and
+// publishes an EventNewAddress for it.
+func (m *Manager) FreshAddress(walletName string) (uint, btcutil.Address, error) {
+	name := resolveWalletName(walletName)
+	wallet, ok := m.Wallet(name)
+	if !ok {
+		return 0, nil, ErrWalletNotFound
+	}
+
+	index, addr, err := wallet.FreshAddress()
+	if err != nil {
+		return 0, nil, err
+	}
+	m.publish(Event{Type: EventNewAddress, WalletName: name, Data: addr})
+	return index, addr, nil
+}
+
+// NotifyNewTransactions fans new mempool transactions out to every loaded wallet. A
+// failure applying the transactions to one wallet is logged and does not affect the
+// others. On success, an EventMempoolTx is published for each transaction, followed by an
+// EventBalanceChanged if the wallet's balance moved.
+func (m *Manager) NotifyNewTransactions(txns []*mempool.TxDesc) {
+	for name, wallet := range m.loadedWallets() {
+		preBalance := wallet.Balance()
+		if err := wallet.ApplyMempoolTransactions(txns); err != nil {
+			log.Errorf("Failed to apply mempool txs to wallet %q. %v", name, err)
+			continue
+		}
+
+		for _, txDesc := range txns {
+			m.publish(Event{Type: EventMempoolTx, WalletName: name, Data: *txDesc.Tx.Hash()})
+		}
+		if postBalance := wallet.Balance(); postBalance != preBalance {
+			m.publish(Event{Type: EventBalanceChanged, WalletName: name, Data: postBalance})
+		}
 	}
 }
 
 func (m *Manager) handleBlockchainNotification(notification *blockchain.Notification) {
-	if m.Wallet == nil {
+	block, ok := notification.Data.(*btcutil.Block)
+	if !ok {
+		log.Warnf("Chain notification is not a block.")
 		return
 	}
+	blockID := BlockId{Height: uint(block.Height()), Hash: *block.Hash()}
 
 	switch notification.Type {
 	// A block has been accepted into the block chain.
 	case blockchain.NTBlockConnected:
-		block, ok := notification.Data.(*btcutil.Block)
-		if !ok {
-			log.Warnf("Chain connected notification is not a block.")
-			return
+		for name, wallet := range m.loadedWallets() {
+			preBalance := wallet.Balance()
+			prePending := unconfirmedTxids(wallet)
+
+			if err := wallet.ApplyBlock(block); err != nil {
+				log.Criticalf("Couldn't apply block to wallet %q. %v", name, err)
+				continue
+			}
+			m.publish(Event{Type: EventBlockConnected, WalletName: name, Data: blockID})
+
+			for txid := range newlyConfirmedTxids(wallet, prePending, blockID.Height) {
+				m.publish(Event{Type: EventTxConfirmed, WalletName: name, Data: txid})
+			}
+			if postBalance := wallet.Balance(); postBalance != preBalance {
+				m.publish(Event{Type: EventBalanceChanged, WalletName: name, Data: postBalance})
+			}
 		}
-		err := m.Wallet.ApplyBlock(block)
-		if err != nil {
-			log.Criticalf("Couldn't apply block to the wallet. %v", err)
+
+	// A block has been removed from the best chain during a reorg.
+	case blockchain.NTBlockDisconnected:
+		for name := range m.loadedWallets() {
+			m.publish(Event{Type: EventBlockDisconnected, WalletName: name, Data: blockID})
 		}
 	}
 }
+
+// loadedWallets returns a snapshot of the currently loaded wallets, safe to range over
+// without holding m.mu.
+func (m *Manager) loadedWallets() map[string]Wallet {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	wallets := make(map[string]Wallet, len(m.wallets))
+	for name, rec := range m.wallets {
+		wallets[name] = rec.wallet
+	}
+	return wallets
+}
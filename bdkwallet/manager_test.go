@@ -0,0 +1,187 @@
+package bdkwallet
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeWallet embeds a nil Wallet so tests only need to override the methods they exercise;
+// calling any other method would panic on the nil interface.
+type fakeWallet struct {
+	Wallet
+
+	lockCalls   int
+	lockErr     error
+	unlockCalls int
+	unlockErr   error
+
+	fundPSBTCalls     int
+	signPSBTCalls     int
+	finalizePSBTCalls int
+
+	// onLock, if set, runs inside Lock before it returns. Tests use it to simulate a
+	// concurrent WalletPassphrase call racing with an in-flight auto-lock.
+	onLock func()
+}
+
+func (f *fakeWallet) Lock() error {
+	f.lockCalls++
+	if f.onLock != nil {
+		f.onLock()
+	}
+	return f.lockErr
+}
+
+func (f *fakeWallet) Unlock(passphrase []byte, timeout time.Duration) error {
+	f.unlockCalls++
+	return f.unlockErr
+}
+
+func (f *fakeWallet) FundPSBT(feerate uint64, recipients []Recipient, opts *PSBTOptions) ([]byte, error) {
+	f.fundPSBTCalls++
+	return []byte("funded"), nil
+}
+
+func (f *fakeWallet) SignPSBT(psbt []byte) ([]byte, error) {
+	f.signPSBTCalls++
+	return []byte("signed"), nil
+}
+
+func (f *fakeWallet) FinalizePSBT(psbt []byte) ([]byte, []byte, error) {
+	f.finalizePSBTCalls++
+	return []byte("final"), []byte("raw"), nil
+}
+
+func TestResolveWalletName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "", want: defaultWalletName},
+		{name: "savings", want: "savings"},
+		{name: defaultWalletName, want: defaultWalletName},
+	}
+	for _, tt := range tests {
+		if got := resolveWalletName(tt.name); got != tt.want {
+			t.Errorf("resolveWalletName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWalletPassphraseUnknownWallet(t *testing.T) {
+	m := &Manager{wallets: make(map[string]*walletRecord)}
+
+	err := m.WalletPassphrase("does-not-exist", []byte("hunter2"), time.Minute)
+	if !errors.Is(err, ErrWalletNotFound) {
+		t.Fatalf("WalletPassphrase() error = %v, want %v", err, ErrWalletNotFound)
+	}
+}
+
+func TestWalletPassphraseSetsUnlockDeadline(t *testing.T) {
+	wallet := &fakeWallet{}
+	m := &Manager{wallets: map[string]*walletRecord{
+		defaultWalletName: {wallet: wallet},
+	}}
+
+	before := time.Now()
+	if err := m.WalletPassphrase("", []byte("hunter2"), time.Minute); err != nil {
+		t.Fatalf("WalletPassphrase() error = %v", err)
+	}
+	if wallet.unlockCalls != 1 {
+		t.Fatalf("Unlock called %d times, want 1", wallet.unlockCalls)
+	}
+
+	deadline := m.wallets[defaultWalletName].unlockDeadline
+	if deadline.Before(before.Add(time.Minute)) || deadline.After(time.Now().Add(time.Minute)) {
+		t.Fatalf("unlockDeadline = %v, want ~%v", deadline, before.Add(time.Minute))
+	}
+}
+
+func TestLockExpiredWallets(t *testing.T) {
+	expired := &fakeWallet{}
+	current := &fakeWallet{}
+	m := &Manager{wallets: map[string]*walletRecord{
+		"expired": {wallet: expired, unlockDeadline: time.Now().Add(-time.Minute)},
+		"current": {wallet: current, unlockDeadline: time.Now().Add(time.Hour)},
+		"locked":  {wallet: &fakeWallet{}}, // zero unlockDeadline: already locked
+	}}
+
+	m.lockExpiredWallets()
+
+	if expired.lockCalls != 1 {
+		t.Errorf("expired wallet Lock() called %d times, want 1", expired.lockCalls)
+	}
+	if !m.wallets["expired"].unlockDeadline.IsZero() {
+		t.Errorf("expired wallet unlockDeadline not cleared: %v", m.wallets["expired"].unlockDeadline)
+	}
+	if current.lockCalls != 0 {
+		t.Errorf("current wallet Lock() called %d times, want 0", current.lockCalls)
+	}
+}
+
+func TestLockExpiredWalletsDoesNotClobberConcurrentUnlock(t *testing.T) {
+	wallet := &fakeWallet{}
+	m := &Manager{wallets: map[string]*walletRecord{
+		"savings": {wallet: wallet, unlockDeadline: time.Now().Add(-time.Minute)},
+	}}
+
+	// Simulate a WalletPassphrase call landing while Lock is in flight: it re-unlocks the
+	// wallet with a fresh, not-yet-expired deadline.
+	freshDeadline := time.Now().Add(time.Hour)
+	wallet.onLock = func() {
+		m.mu.Lock()
+		m.wallets["savings"].unlockDeadline = freshDeadline
+		m.mu.Unlock()
+	}
+
+	m.lockExpiredWallets()
+
+	if wallet.lockCalls != 1 {
+		t.Fatalf("Lock() called %d times, want 1", wallet.lockCalls)
+	}
+	if got := m.wallets["savings"].unlockDeadline; !got.Equal(freshDeadline) {
+		t.Errorf("unlockDeadline = %v, want the concurrently-set %v (must not be clobbered)", got, freshDeadline)
+	}
+}
+
+func TestPSBTDispatchRoutesToNamedWallet(t *testing.T) {
+	wallet := &fakeWallet{}
+	m := &Manager{wallets: map[string]*walletRecord{
+		"savings": {wallet: wallet},
+	}}
+
+	if _, err := m.FundPSBT("savings", 1, nil, nil); err != nil {
+		t.Fatalf("FundPSBT() error = %v", err)
+	}
+	if _, err := m.SignPSBT("savings", []byte("funded")); err != nil {
+		t.Fatalf("SignPSBT() error = %v", err)
+	}
+	if _, _, err := m.FinalizePSBT("savings", []byte("signed")); err != nil {
+		t.Fatalf("FinalizePSBT() error = %v", err)
+	}
+
+	if wallet.fundPSBTCalls != 1 {
+		t.Errorf("FundPSBT called %d times, want 1", wallet.fundPSBTCalls)
+	}
+	if wallet.signPSBTCalls != 1 {
+		t.Errorf("SignPSBT called %d times, want 1", wallet.signPSBTCalls)
+	}
+	if wallet.finalizePSBTCalls != 1 {
+		t.Errorf("FinalizePSBT called %d times, want 1", wallet.finalizePSBTCalls)
+	}
+}
+
+func TestPSBTDispatchUnknownWallet(t *testing.T) {
+	m := &Manager{wallets: make(map[string]*walletRecord)}
+
+	if _, err := m.FundPSBT("does-not-exist", 1, nil, nil); !errors.Is(err, ErrWalletNotFound) {
+		t.Errorf("FundPSBT() error = %v, want %v", err, ErrWalletNotFound)
+	}
+	if _, err := m.SignPSBT("does-not-exist", nil); !errors.Is(err, ErrWalletNotFound) {
+		t.Errorf("SignPSBT() error = %v, want %v", err, ErrWalletNotFound)
+	}
+	if _, _, err := m.FinalizePSBT("does-not-exist", nil); !errors.Is(err, ErrWalletNotFound) {
+		t.Errorf("FinalizePSBT() error = %v, want %v", err, ErrWalletNotFound)
+	}
+}
@@ -0,0 +1,201 @@
+package bdkwallet
+
+import "github.com/utreexo/utreexod/chaincfg/chainhash"
+
+// subscriberBufferSize is how many events a subscriber can fall behind by before new
+// events are dropped for it.
+const subscriberBufferSize = 64
+
+// EventType identifies the kind of wallet state change an Event reports.
+type EventType int
+
+const (
+	// EventBlockConnected fires when a new block has been applied to a wallet. Data is a
+	// BlockId.
+	EventBlockConnected EventType = iota
+	// EventBlockDisconnected fires when a block has been removed from the best chain
+	// during a reorg. Data is a BlockId.
+	EventBlockDisconnected
+	// EventMempoolTx fires when a new mempool transaction has been applied to a wallet.
+	// Data is the transaction's chainhash.Hash.
+	EventMempoolTx
+	// EventTxConfirmed fires when a transaction that was unconfirmed moves into a
+	// connected block. Data is the transaction's chainhash.Hash.
+	EventTxConfirmed
+	// EventBalanceChanged fires when applying a block or mempool transactions changes a
+	// wallet's balance. Data is the wallet's new Balance.
+	EventBalanceChanged
+	// EventNewAddress fires when a wallet hands out a fresh address. Data is the
+	// btcutil.Address.
+	EventNewAddress
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventBlockConnected:
+		return "block-connected"
+	case EventBlockDisconnected:
+		return "block-disconnected"
+	case EventMempoolTx:
+		return "mempool-tx"
+	case EventTxConfirmed:
+		return "tx-confirmed"
+	case EventBalanceChanged:
+		return "balance-changed"
+	case EventNewAddress:
+		return "new-address"
+	default:
+		return "unknown"
+	}
+}
+
+// Event reports a single wallet state change to subscribers.
+type Event struct {
+	Type       EventType
+	WalletName string
+	Data       interface{}
+}
+
+// EventFilter restricts a Subscription to events matching it. A nil or empty slice in
+// either field matches everything.
+type EventFilter struct {
+	WalletNames []string
+	Types       []EventType
+}
+
+func (f EventFilter) matches(ev Event) bool {
+	if len(f.Types) > 0 && !containsType(f.Types, ev.Type) {
+		return false
+	}
+	if len(f.WalletNames) > 0 && !containsString(f.WalletNames, ev.WalletName) {
+		return false
+	}
+	return true
+}
+
+func containsType(types []EventType, t EventType) bool {
+	for _, candidate := range types {
+		if candidate == t {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(strs []string, s string) bool {
+	for _, candidate := range strs {
+		if candidate == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscription is a live feed of wallet Events matching an EventFilter, obtained from
+// Manager.Subscribe.
+type Subscription struct {
+	C <-chan Event
+
+	id uint64
+	m  *Manager
+}
+
+// Cancel unregisters the subscription. After Cancel returns, no further events will be
+// sent on C.
+func (s *Subscription) Cancel() {
+	s.m.unsubscribe(s.id)
+}
+
+// subscriber is a Subscription's internal state, held by the Manager.
+type subscriber struct {
+	ch     chan Event
+	filter EventFilter
+}
+
+// Subscribe returns a live feed of wallet Events matching filter. The feed has a bounded
+// buffer; if the caller falls behind, new events are dropped for it and a warning is
+// logged rather than blocking the chain-notification goroutine.
+func (m *Manager) Subscribe(filter EventFilter) (*Subscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextSubID++
+	id := m.nextSubID
+	ch := make(chan Event, subscriberBufferSize)
+	m.subscribers[id] = &subscriber{ch: ch, filter: filter}
+
+	return &Subscription{C: ch, id: id, m: m}, nil
+}
+
+// unsubscribe removes and closes the channel for subscription id, if it still exists.
+func (m *Manager) unsubscribe(id uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sub, ok := m.subscribers[id]
+	if !ok {
+		return
+	}
+	delete(m.subscribers, id)
+	close(sub.ch)
+}
+
+// publish delivers ev to every matching subscriber without blocking. A subscriber that
+// can't keep up has ev dropped for it instead. The sends happen under m.mu so that
+// unsubscribe can't close a channel while an event is being sent on it.
+func (m *Manager) publish(ev Event) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, sub := range m.subscribers {
+		if !sub.filter.matches(ev) {
+			continue
+		}
+		select {
+		case sub.ch <- ev:
+		default:
+			log.Warnf("Dropping wallet event %v for wallet %q: subscriber is falling behind.",
+				ev.Type, ev.WalletName)
+		}
+	}
+}
+
+// unconfirmedTxids returns the set of txids the wallet currently reports as unconfirmed.
+func unconfirmedTxids(wallet Wallet) map[chainhash.Hash]struct{} {
+	page, err := wallet.ListTransactions(TxQuery{EndHeight: UnconfirmedHeight})
+	if err != nil {
+		return nil
+	}
+	ids := make(map[chainhash.Hash]struct{}, len(page.Transactions))
+	for _, tx := range page.Transactions {
+		ids[tx.Txid] = struct{}{}
+	}
+	return ids
+}
+
+// newlyConfirmedTxids returns the subset of prePending that was confirmed in the block at
+// height. It queries ListTransactions bounded to that single height rather than pulling the
+// wallet's full history (wallet.Transactions()), which is exactly the per-block cost
+// ListTransactions/TxQuery was added to let callers avoid. A txid merely leaving the
+// unconfirmed set isn't enough proof on its own that it was mined: that's also what happens
+// when a mempool tx is RBF-replaced or otherwise evicted by a connected block without being
+// mined, so this checks that the txid is actually present among height's confirmed
+// transactions rather than just absent from the unconfirmed set.
+func newlyConfirmedTxids(wallet Wallet, prePending map[chainhash.Hash]struct{}, height uint) map[chainhash.Hash]struct{} {
+	if len(prePending) == 0 {
+		return nil
+	}
+
+	page, err := wallet.ListTransactions(TxQuery{StartHeight: int32(height), EndHeight: int32(height)})
+	if err != nil {
+		return nil
+	}
+
+	confirmed := make(map[chainhash.Hash]struct{})
+	for _, tx := range page.Transactions {
+		if _, wasPending := prePending[tx.Txid]; wasPending {
+			confirmed[tx.Txid] = struct{}{}
+		}
+	}
+	return confirmed
+}
@@ -2,6 +2,7 @@ package bdkwallet
 
 import (
 	"errors"
+	"time"
 
 	"github.com/utreexo/utreexod/btcutil"
 	"github.com/utreexo/utreexod/chaincfg"
@@ -12,6 +13,8 @@ import (
 var (
 	ErrNoRecipient = errors.New("must have atleast one recipient")
 	ErrNoBDK       = errors.New("utreexod must be built with the 'bdkwallet' tag to enable the BDK wallet")
+	ErrLocked      = errors.New("wallet is locked")
+	ErrWatchOnly   = errors.New("wallet is watch-only")
 )
 
 // walletFactory is nil unless we build with the 'bdkwallet' build tag.
@@ -27,8 +30,35 @@ func factory() (WalletFactory, error) {
 
 // WalletFactory creates wallets.
 type WalletFactory interface {
-	Create(dbPath string, chainParams *chaincfg.Params) (Wallet, error)
+	// Create initializes a new wallet at dbPath. If seed is nil, a new mnemonic is
+	// generated; otherwise the wallet is restored from seed. Private key material is
+	// encrypted at rest with privPassphrase; address and transaction metadata is encrypted
+	// with pubPassphrase. Either may be nil to use the wallet's default passphrase.
+	Create(dbPath string, pubPassphrase, privPassphrase, seed []byte, chainParams *chaincfg.Params) (Wallet, error)
 	Load(dbPath string, chainParams *chaincfg.Params) (Wallet, error)
+	// CreateWatchOnly initializes a watch-only wallet from a BIP-380 output descriptor,
+	// e.g. "wpkh([fingerprint/84'/0'/0']xpub.../0/*)". To track a separate change chain,
+	// join the external and internal descriptors with a newline.
+	CreateWatchOnly(dbPath string, descriptor string, chainParams *chaincfg.Params) (Wallet, error)
+	// JoinPSBTs merges the inputs and outputs of the given PSBTs into a single, unsigned
+	// PSBT. It has no access to any one wallet's keys, which is why it hangs off the
+	// factory rather than a Wallet.
+	JoinPSBTs(psbts [][]byte) ([]byte, error)
+}
+
+// CreateOpts customizes wallet creation through Manager.CreateWallet.
+type CreateOpts struct {
+	// DescriptorType identifies the wallet's address/descriptor scheme (e.g. "bip84",
+	// "bip49"). It is recorded in the wallet index but is otherwise opaque to the Manager.
+	DescriptorType string
+
+	// PublicPassphrase encrypts address and transaction metadata. If nil, the wallet's
+	// default public passphrase is used.
+	PublicPassphrase []byte
+
+	// PrivatePassphrase encrypts private key material. If nil, the wallet's default
+	// private passphrase is used and the wallet is created unlocked.
+	PrivatePassphrase []byte
 }
 
 // Wallet tracks addresses and transactions sending/receiving to/from those addresses. The wallet is
@@ -41,10 +71,60 @@ type Wallet interface {
 	RecentBlocks(count uint32) []BlockId
 	ApplyBlock(block *btcutil.Block) error
 	ApplyMempoolTransactions(txns []*mempool.TxDesc) error
+	// CreateTx builds, signs, and finalizes a transaction spending from this wallet in a
+	// single step. It is implemented in terms of FundPSBT, SignPSBT, and FinalizePSBT.
+	// Returns ErrLocked if the wallet is locked, or ErrWatchOnly if the wallet is watch-only.
 	CreateTx(feerate uint64, recipients []Recipient) ([]byte, error)
+	FundPSBT(feerate uint64, recipients []Recipient, opts *PSBTOptions) ([]byte, error)
+	// SignPSBT adds partial signatures for inputs this wallet owns. Returns ErrLocked if
+	// the wallet is locked.
+	SignPSBT(psbt []byte) ([]byte, error)
+	FinalizePSBT(psbt []byte) (finalPsbt []byte, rawTx []byte, err error)
+	// MnemonicWords returns the wallet's seed mnemonic. It has no error return, so it does
+	// not report ErrLocked for a locked wallet or ErrWatchOnly for a watch-only wallet.
 	MnemonicWords() []string
+	// Transactions returns the wallet's full transaction history. It is a thin wrapper
+	// around ListTransactions kept for backward compatibility; new callers should prefer
+	// ListTransactions so they can page through history instead of pulling all of it.
 	Transactions() ([]TxInfo, error)
+	// ListTransactions returns a page of the wallet's transaction history matching req.
+	ListTransactions(req TxQuery) (TxPage, error)
 	UTXOs() []UTXOInfo
+
+	// ExportDescriptors returns the wallet's external (receive) and internal (change)
+	// output descriptors, so a signing wallet can hand its public descriptor to a
+	// watch-only companion.
+	ExportDescriptors() (external, internal string, err error)
+
+	// Unlock decrypts the wallet's private key material with passphrase, re-locking it
+	// automatically after timeout unless timeout is 0, meaning no auto-lock.
+	Unlock(passphrase []byte, timeout time.Duration) error
+	// Lock re-encrypts the wallet's private key material immediately.
+	Lock() error
+	// IsLocked reports whether the wallet's private key material is currently encrypted.
+	IsLocked() bool
+	// ChangePassphrase re-encrypts the wallet with newPass. If private is true, the
+	// private passphrase is changed; otherwise the public passphrase is changed.
+	ChangePassphrase(oldPass, newPass []byte, private bool) error
+}
+
+// JoinPSBTs merges the inputs and outputs of the given PSBTs into a single, unsigned PSBT.
+// It is used by coordinator flows (e.g. CoinJoin-style transactions) that assemble a
+// transaction out of pieces contributed by multiple parties. It is a package-level function
+// rather than a Wallet method since joining does not require access to any one wallet's keys.
+func JoinPSBTs(psbts [][]byte) ([]byte, error) {
+	factory, err := factory()
+	if err != nil {
+		return nil, err
+	}
+	return factory.JoinPSBTs(psbts)
+}
+
+// PSBTOptions customizes coin selection and output handling for FundPSBT.
+type PSBTOptions struct {
+	ChangeAddress *btcutil.Address // address to send change to; a fresh wallet address is used if nil
+	LockUnspents  bool             // lock the selected UTXOs so they aren't chosen by a concurrent FundPSBT call
+	IncludeUnsafe bool             // allow selecting unconfirmed UTXOs not in our own change keychain
 }
 
 // Balance in satoshis.
@@ -77,6 +157,29 @@ type Recipient struct {
 	Address string         // recipient address to send to (in human-readable form)
 }
 
+// UnconfirmedHeight is the EndHeight value that restricts a TxQuery to unconfirmed
+// (mempool) transactions only.
+const UnconfirmedHeight = -1
+
+// TxQuery filters and pages a ListTransactions call.
+type TxQuery struct {
+	StartHeight int32 // minimum confirmation height to include, inclusive
+	EndHeight   int32 // maximum confirmation height to include, inclusive; UnconfirmedHeight for mempool-only
+	Limit       int   // maximum number of transactions to return; 0 means no limit
+	Offset      int   // number of matching transactions to skip, for paging
+
+	// AddressFilter restricts results to transactions paying to or from one of these
+	// addresses. A nil slice matches all addresses.
+	AddressFilter []btcutil.Address
+}
+
+// TxPage is a page of transaction history returned by ListTransactions.
+type TxPage struct {
+	Transactions []TxInfo
+	NextOffset   int // Offset to pass in the next TxQuery to continue after this page
+	Total        int // total number of transactions matching the query, across all pages
+}
+
 // TxInfo is information on a given transaction.
 type TxInfo struct {
 	Txid          chainhash.Hash
@@ -0,0 +1,21 @@
+package bdkwallet
+
+import "testing"
+
+func TestJoinPSBTsRequiresBDK(t *testing.T) {
+	if walletFactory != nil {
+		t.Skip("a WalletFactory is registered; this binary was built with the bdkwallet tag")
+	}
+	if _, err := JoinPSBTs([][]byte{{0x01}, {0x02}}); err != ErrNoBDK {
+		t.Fatalf("JoinPSBTs() error = %v, want %v", err, ErrNoBDK)
+	}
+}
+
+func TestFactoryRequiresBDK(t *testing.T) {
+	if walletFactory != nil {
+		t.Skip("a WalletFactory is registered; this binary was built with the bdkwallet tag")
+	}
+	if _, err := factory(); err != ErrNoBDK {
+		t.Fatalf("factory() error = %v, want %v", err, ErrNoBDK)
+	}
+}